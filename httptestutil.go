@@ -1,27 +1,50 @@
 package httptestutil
 
 import (
+	"bytes"
 	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"testing"
 )
 
+// updateGolden is checked by ResponseBodyGolden/ResponseJSONGolden; pass -update when running
+// tests to overwrite golden files with the actual response instead of asserting against them.
+var updateGolden = flag.Bool("update", false, "overwrite golden files with actual test output")
+
 type Check func(*testing.T)
 
 type ResponseAssertion func(*testing.T, *httptest.ResponseRecorder)
 
+// LiveResponseAssertion validates a real HTTP round-trip, for use with TestSet.RunServer
+type LiveResponseAssertion func(*testing.T, *http.Response)
+
 type RequestModifier func(req *http.Request)
 
+// RequestAssertion validates the request as received by the handler under test, for handlers
+// that themselves proxy or forward the incoming request onward
+type RequestAssertion func(*testing.T, *http.Request)
+
 type TestConfig struct {
-	name       string
-	method     string
-	route      string
-	body       string
-	modifiers  []RequestModifier
-	assertions []ResponseAssertion
+	name             string
+	method           string
+	route            string
+	bodyFactory      func() io.Reader
+	modifiers        []RequestModifier
+	assertions       []ResponseAssertion
+	requestAssertions []RequestAssertion
 	precheck	 []Check
 	postcheck  []Check
 }
@@ -39,11 +62,228 @@ func (tests TestSet) Run(t *testing.T, handler http.Handler) {
 				check(t)
 			}
 
-			req, err := http.NewRequest(test.method, test.route, strings.NewReader(test.body))
+			req, err := http.NewRequest(test.method, test.route, test.bodyFactory())
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			// A real client always drains and closes the request body as part of the round trip;
+			// ServeHTTP does not, so closing it here unblocks a lazily-streamed body (e.g.
+			// RequestMultipart) if handler never reads it to EOF, rather than leaking its
+			// producer goroutine.
+			defer req.Body.Close()
+
+			for _, modifier := range test.modifiers {
+				modifier(req)
+			}
+
+			recorder := httptest.NewRecorder()
+
+			effectiveHandler := handler
+			if len(test.requestAssertions) > 0 {
+				effectiveHandler = recordingHandler(t, test.requestAssertions, handler)
+			}
+
+			effectiveHandler.ServeHTTP(recorder, req)
+
+			for _, assert := range test.assertions {
+				assert(t, recorder)
+			}
+
+			for _, check := range test.postcheck {
+				check(t)
+			}
+		})
+	}
+}
+
+// recordingHandler wraps handler so the request it receives is captured and validated against
+// assertions before the underlying handler runs. The request body is buffered and restored after
+// each assertion so handler still sees a fresh, unconsumed body.
+func recordingHandler(t *testing.T, assertions []RequestAssertion, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var body []byte
+		if req.Body != nil {
+			body, _ = io.ReadAll(req.Body)
+		}
+
+		for _, assert := range assertions {
+			req.Body = io.NopCloser(bytes.NewReader(body))
+			assert(t, req)
+		}
+
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		handler.ServeHTTP(w, req)
+	})
+}
+
+// ExpectRequests attaches RequestAssertions that validate the request as received by the handler
+// under test, for testing handlers that proxy or forward the request onward
+func ExpectRequests(assertions ...RequestAssertion) TestOption {
+	return func(test *TestConfig) {
+		test.requestAssertions = append(test.requestAssertions, assertions...)
+	}
+}
+
+// AssertRequestMethod asserts that the request received has the expected method
+func AssertRequestMethod(expected string) RequestAssertion {
+	return func(t *testing.T, req *http.Request) {
+		if req.Method != expected {
+			t.Errorf("Unexpected request method: received '%s' expected '%s'", req.Method, expected)
+		}
+	}
+}
+
+// AssertRequestPath asserts that the request received has the expected URL path
+func AssertRequestPath(expected string) RequestAssertion {
+	return func(t *testing.T, req *http.Request) {
+		if req.URL.Path != expected {
+			t.Errorf("Unexpected request path: received '%s' expected '%s'", req.URL.Path, expected)
+		}
+	}
+}
+
+// AssertRequestHeader asserts that the request received has the expected header
+func AssertRequestHeader(header string, expected string) RequestAssertion {
+	return func(t *testing.T, req *http.Request) {
+		if actual := req.Header.Get(header); actual != expected {
+			t.Errorf("Unexpected request header value for '%s': received '%s' expected '%s'", header, actual, expected)
+		}
+	}
+}
+
+// AssertRequestJSONBody asserts that the request body is JSON-equivalent to expected, ignoring
+// key ordering and whitespace
+func AssertRequestJSONBody(expected interface{}) RequestAssertion {
+	return func(t *testing.T, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			t.Errorf("Could not read request body: %v", err)
+			return
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+
+		var actualValue interface{}
+		if err := json.Unmarshal(body, &actualValue); err != nil {
+			t.Errorf("Could not JSON parse request body: received\n\n%s", body)
+			return
+		}
+
+		expectedBytes, err := json.Marshal(expected)
+		if err != nil {
+			t.Fatalf("Could not marshal expected value: %v", err)
+		}
+
+		var expectedValue interface{}
+		if err := json.Unmarshal(expectedBytes, &expectedValue); err != nil {
+			t.Fatalf("Could not JSON parse expected value: %v", err)
+		}
+
+		if diff := jsonDiff("", actualValue, expectedValue); len(diff) > 0 {
+			t.Errorf("Unexpected request JSON body: received\n\n%s\n\nexpected\n\n%s\n\ndiff:\n%v", body, string(expectedBytes), strings.Join(diff, "\n"))
+		}
+	}
+}
+
+// TestSet.RunServer runs the tests against a real httptest.Server backed by handler, dispatching
+// actual HTTP round-trips through http.DefaultClient instead of calling ServeHTTP on a recorder.
+// This exercises middleware that depends on real TCP semantics (chunked encoding, connection
+// reuse) that ServeHTTP cannot reproduce. Existing ResponseAssertion-based options are adapted to
+// run against the real *http.Response.
+func (tests TestSet) RunServer(t *testing.T, handler http.Handler) {
+	var currentT *testing.T
+	var currentRequestAssertions []RequestAssertion
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		effectiveHandler := handler
+		if len(currentRequestAssertions) > 0 {
+			effectiveHandler = recordingHandler(currentT, currentRequestAssertions, handler)
+		}
+		effectiveHandler.ServeHTTP(w, req)
+	}))
+	defer server.Close()
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			currentT = t
+			currentRequestAssertions = test.requestAssertions
+
+			for _, check := range test.precheck {
+				check(t)
+			}
+
+			req, err := http.NewRequest(test.method, server.URL+test.route, test.bodyFactory())
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, modifier := range test.modifiers {
+				modifier(req)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			for _, assert := range test.assertions {
+				adaptResponseAssertion(assert)(t, resp)
+			}
+
+			for _, check := range test.postcheck {
+				check(t)
+			}
+		})
+	}
+}
+
+// adaptResponseAssertion lets a ResponseAssertion written against a *httptest.ResponseRecorder
+// run against a real *http.Response by buffering the response into an equivalent recorder.
+func adaptResponseAssertion(assertion ResponseAssertion) LiveResponseAssertion {
+	return func(t *testing.T, resp *http.Response) {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("Could not read response body: %v", err)
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+
+		recorder := httptest.NewRecorder()
+		recorder.Code = resp.StatusCode
+		for header, values := range resp.Header {
+			recorder.Header()[header] = values
+		}
+		recorder.Body = bytes.NewBuffer(body)
+
+		assertion(t, recorder)
+	}
+}
+
+// TestSet.RunParallel runs the tests against handler, calling t.Parallel() inside each subtest.
+// Each subtest runs against a deep copy of its TestConfig's modifier/assertion slices so that
+// concurrent subtests never share a backing array; any user-supplied Before/After checks must be
+// goroutine-safe, since they may run concurrently with other subtests' checks.
+func (tests TestSet) RunParallel(t *testing.T, handler http.Handler) {
+	for _, test := range tests {
+		test := test.clone()
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			for _, check := range test.precheck {
+				check(t)
+			}
+
+			req, err := http.NewRequest(test.method, test.route, test.bodyFactory())
 
 			if err != nil {
 				t.Fatal(err)
 			}
+			// See the matching comment in Run: ServeHTTP doesn't drain the body itself, so close
+			// it here to unblock a lazily-streamed body's producer goroutine if handler never
+			// reads it to EOF.
+			defer req.Body.Close()
 
 			for _, modifier := range test.modifiers {
 				modifier(req)
@@ -51,7 +291,12 @@ func (tests TestSet) Run(t *testing.T, handler http.Handler) {
 
 			recorder := httptest.NewRecorder()
 
-			handler.ServeHTTP(recorder, req)
+			effectiveHandler := handler
+			if len(test.requestAssertions) > 0 {
+				effectiveHandler = recordingHandler(t, test.requestAssertions, handler)
+			}
+
+			effectiveHandler.ServeHTTP(recorder, req)
 
 			for _, assert := range test.assertions {
 				assert(t, recorder)
@@ -64,15 +309,28 @@ func (tests TestSet) Run(t *testing.T, handler http.Handler) {
 	}
 }
 
+// clone returns a copy of test whose slice fields have their own backing arrays, so a subtest
+// running in parallel cannot observe appends made by another subtest's copy.
+func (test TestConfig) clone() TestConfig {
+	clone := test
+	clone.modifiers = append([]RequestModifier(nil), test.modifiers...)
+	clone.assertions = append([]ResponseAssertion(nil), test.assertions...)
+	clone.requestAssertions = append([]RequestAssertion(nil), test.requestAssertions...)
+	clone.precheck = append([]Check(nil), test.precheck...)
+	clone.postcheck = append([]Check(nil), test.postcheck...)
+	return clone
+}
+
 // Test creates a single test object
 func Test(name string, options ...TestOption) TestConfig {
 	test := TestConfig{
 		name,
 		"",
 		"",
-		"",
+		func() io.Reader { return strings.NewReader("") },
 		[]RequestModifier{},
 		[]ResponseAssertion{},
+		[]RequestAssertion{},
 		[]Check{},
 		[]Check{},
 	}
@@ -87,6 +345,132 @@ func Test(name string, options ...TestOption) TestConfig {
 /*
 ---
 
+Suite
+
+A fluent builder alternative to Test for assembling a TestSet, e.g.
+
+	suite := NewSuite().
+		Get("/x").WithHeader("Authorization", "Bearer token").ExpectStatus(200).Add().
+		Post("/y").WithJSON(payload).ExpectStatus(201).Add()
+
+	suite.Build().Run(t, handler)
+
+---
+*/
+
+// Suite accumulates TestConfig values assembled via the fluent builder
+type Suite struct {
+	tests TestSet
+}
+
+// NewSuite creates an empty Suite
+func NewSuite() *Suite {
+	return &Suite{}
+}
+
+// Build returns the TestSet assembled so far
+func (s *Suite) Build() TestSet {
+	return s.tests
+}
+
+// SuiteBuilder builds a single TestConfig for a Suite via method chaining
+type SuiteBuilder struct {
+	suite   *Suite
+	name    string
+	method  string
+	route   string
+	options []TestOption
+}
+
+func (s *Suite) request(method string, route string) *SuiteBuilder {
+	return &SuiteBuilder{suite: s, method: method, route: route}
+}
+
+// Get starts a GET request test
+func (s *Suite) Get(route string) *SuiteBuilder {
+	return s.request(http.MethodGet, route)
+}
+
+// Post starts a POST request test
+func (s *Suite) Post(route string) *SuiteBuilder {
+	return s.request(http.MethodPost, route)
+}
+
+// Put starts a PUT request test
+func (s *Suite) Put(route string) *SuiteBuilder {
+	return s.request(http.MethodPut, route)
+}
+
+// Delete starts a DELETE request test
+func (s *Suite) Delete(route string) *SuiteBuilder {
+	return s.request(http.MethodDelete, route)
+}
+
+// Named overrides the subtest name, which otherwise defaults to "<method> <route>"
+func (b *SuiteBuilder) Named(name string) *SuiteBuilder {
+	b.name = name
+	return b
+}
+
+// WithHeader sets a header on the request
+func (b *SuiteBuilder) WithHeader(header string, value string) *SuiteBuilder {
+	b.options = append(b.options, RequestHeader(header, value))
+	return b
+}
+
+// WithBody sets the body of the request
+func (b *SuiteBuilder) WithBody(body string) *SuiteBuilder {
+	b.options = append(b.options, RequestBody(body))
+	return b
+}
+
+// WithJSON sets the body and content type of the request from a JSON-encodable value
+func (b *SuiteBuilder) WithJSON(d interface{}) *SuiteBuilder {
+	b.options = append(b.options, RequestJSON(d))
+	return b
+}
+
+// ExpectStatus asserts that the response has the expected status
+func (b *SuiteBuilder) ExpectStatus(expectedStatus int) *SuiteBuilder {
+	b.options = append(b.options, ResponseStatus(expectedStatus))
+	return b
+}
+
+// ExpectBody asserts that the response has the expected body
+func (b *SuiteBuilder) ExpectBody(expectedBody string) *SuiteBuilder {
+	b.options = append(b.options, ResponseBody(expectedBody))
+	return b
+}
+
+// ExpectHeader asserts that the response has the expected header
+func (b *SuiteBuilder) ExpectHeader(header string, expected string) *SuiteBuilder {
+	b.options = append(b.options, ResponseHeader(header, expected))
+	return b
+}
+
+// ExpectJSON asserts that the response body is JSON-equivalent to expected
+func (b *SuiteBuilder) ExpectJSON(expected interface{}) *SuiteBuilder {
+	b.options = append(b.options, ResponseJSONEq(expected))
+	return b
+}
+
+// Add assembles the built TestConfig, appends it to the Suite, and returns the Suite so another
+// request can be chained
+func (b *SuiteBuilder) Add() *Suite {
+	name := b.name
+	if name == "" {
+		name = b.method + " " + b.route
+	}
+
+	options := append([]TestOption{RequestMethod(b.method), RequestRel(b.route)}, b.options...)
+	b.suite.tests = append(b.suite.tests, Test(name, options...))
+
+	return b.suite
+}
+
+/*
+---
+
 RequestModifiers
 
 These modify the request going out
@@ -121,17 +505,96 @@ func RequestJSON(d interface{}) TestOption {
 		test.modifiers = append(test.modifiers, func(req *http.Request) {
 			req.Header.Set("Content-Type", "application/json")
 		})
-		test.body = string(s)
+		test.bodyFactory = func() io.Reader { return strings.NewReader(string(s)) }
 	}
 }
 
 // RequestBody sets the body for a request
 func RequestBody(body string) TestOption {
 	return func(test *TestConfig) {
-		test.body = body
+		test.bodyFactory = func() io.Reader { return strings.NewReader(body) }
 	}
 }
 
+// RequestStream sets the body for a request to the contents of r. Since r can only be read once,
+// a TestConfig built with RequestStream is only safe to run a single time (it should not be
+// reused across multiple TestSet.Run calls, or with RunParallel).
+func RequestStream(r io.Reader) TestOption {
+	return func(test *TestConfig) {
+		test.bodyFactory = func() io.Reader { return r }
+	}
+}
+
+// RequestForm sets the body for a request to a url-encoded form and sets the correct content type
+func RequestForm(values url.Values) TestOption {
+	encoded := values.Encode()
+	return func(test *TestConfig) {
+		test.modifiers = append(test.modifiers, func(req *http.Request) {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		})
+		test.bodyFactory = func() io.Reader { return strings.NewReader(encoded) }
+	}
+}
+
+// FileSpec describes a file to attach to a RequestMultipart body. Content may be backed by an
+// in-memory byte slice (bytes.NewReader) or an io.Reader streaming from disk, for uploads too
+// large to hold in memory.
+type FileSpec struct {
+	Filename string
+	Content  io.Reader
+}
+
+// RequestMultipart sets the body for a request to a multipart/form-data body built from fields
+// and files, and sets the correct content type, including boundary. The body is built lazily,
+// streaming fields and files directly to the request as it is sent via an io.Pipe, rather than
+// buffering the whole body in memory; this matters for file sources backed by an io.Reader that
+// is too large to hold in memory at once. As with RequestStream, a FileSpec backed by a
+// non-replayable io.Reader is only safe to send once.
+func RequestMultipart(fields map[string]string, files map[string]FileSpec) TestOption {
+	return func(test *TestConfig) {
+		var contentType string
+
+		test.modifiers = append(test.modifiers, func(req *http.Request) {
+			req.Header.Set("Content-Type", contentType)
+		})
+
+		test.bodyFactory = func() io.Reader {
+			pr, pw := io.Pipe()
+			writer := multipart.NewWriter(pw)
+			contentType = writer.FormDataContentType()
+
+			go func() {
+				err := writeMultipartBody(writer, fields, files)
+				pw.CloseWithError(err)
+			}()
+
+			return pr
+		}
+	}
+}
+
+// writeMultipartBody writes fields and files to writer and closes it, returning the first error
+// encountered, if any
+func writeMultipartBody(writer *multipart.Writer, fields map[string]string, files map[string]FileSpec) error {
+	for key, value := range fields {
+		if err := writer.WriteField(key, value); err != nil {
+			return err
+		}
+	}
+
+	for field, file := range files {
+		part, err := writer.CreateFormFile(field, file.Filename)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(part, file.Content); err != nil {
+			return err
+		}
+	}
+
+	return writer.Close()
+}
+
 // RequestRel sets the relative url for the request (i.e. "/abc")
 func RequestRel(rel string) TestOption {
 	return func(test *TestConfig) {
@@ -191,6 +654,126 @@ func ResponseHeader(header string, expected string) TestOption {
 	})
 }
 
+// ResponseJSONEq asserts that the response body is JSON-equivalent to expected,
+// ignoring key ordering and whitespace. On mismatch it reports a structured,
+// path-by-path diff rather than a raw string comparison.
+func ResponseJSONEq(expected interface{}) TestOption {
+	return responseAssertion(func(t *testing.T, rr *httptest.ResponseRecorder) {
+		expectedBytes, err := json.Marshal(expected)
+		if err != nil {
+			t.Fatalf("Could not marshal expected value: %v", err)
+		}
+
+		var expectedValue interface{}
+		if err := json.Unmarshal(expectedBytes, &expectedValue); err != nil {
+			t.Fatalf("Could not JSON parse expected value: %v", err)
+		}
+
+		var actualValue interface{}
+		if err := json.Unmarshal(rr.Body.Bytes(), &actualValue); err != nil {
+			t.Errorf("Could not JSON parse response body: received\n\n%v", rr.Body)
+			return
+		}
+
+		if diff := jsonDiff("", actualValue, expectedValue); len(diff) > 0 {
+			t.Errorf("Unexpected JSON body: received\n\n%v\n\nexpected\n\n%v\n\ndiff:\n%v", rr.Body, string(expectedBytes), strings.Join(diff, "\n"))
+		}
+	})
+}
+
+// jsonDiff recursively walks two decoded JSON values together and returns
+// path-prefixed diff lines: "+ .path = v" for values only present in actual,
+// "- .path = v" for values only present in expected, and "~ .path: a -> b"
+// for scalar values that differ.
+func jsonDiff(path string, actual interface{}, expected interface{}) []string {
+	switch exp := expected.(type) {
+	case map[string]interface{}:
+		act, ok := actual.(map[string]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("~ %s: %s -> %s", path, formatJSON(expected), formatJSON(actual))}
+		}
+		return jsonDiffMaps(path, act, exp)
+	case []interface{}:
+		act, ok := actual.([]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("~ %s: %s -> %s", path, formatJSON(expected), formatJSON(actual))}
+		}
+		return jsonDiffArrays(path, act, exp)
+	default:
+		if !reflect.DeepEqual(actual, expected) {
+			return []string{fmt.Sprintf("~ %s: %s -> %s", path, formatJSON(expected), formatJSON(actual))}
+		}
+		return nil
+	}
+}
+
+func jsonDiffMaps(path string, actual map[string]interface{}, expected map[string]interface{}) []string {
+	keys := make(map[string]bool)
+	for k := range actual {
+		keys[k] = true
+	}
+	for k := range expected {
+		keys[k] = true
+	}
+
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	var diffs []string
+	for _, k := range sorted {
+		childPath := path + "." + k
+		actualValue, actualOk := actual[k]
+		expectedValue, expectedOk := expected[k]
+
+		switch {
+		case actualOk && !expectedOk:
+			diffs = append(diffs, fmt.Sprintf("+ %s = %s", childPath, formatJSON(actualValue)))
+		case !actualOk && expectedOk:
+			diffs = append(diffs, fmt.Sprintf("- %s = %s", childPath, formatJSON(expectedValue)))
+		default:
+			diffs = append(diffs, jsonDiff(childPath, actualValue, expectedValue)...)
+		}
+	}
+
+	return diffs
+}
+
+func jsonDiffArrays(path string, actual []interface{}, expected []interface{}) []string {
+	max := len(actual)
+	if len(expected) > max {
+		max = len(expected)
+	}
+
+	var diffs []string
+	for i := 0; i < max; i++ {
+		childPath := fmt.Sprintf("%s[%d]", path, i)
+
+		switch {
+		case i >= len(expected):
+			diffs = append(diffs, fmt.Sprintf("+ %s = %s", childPath, formatJSON(actual[i])))
+		case i >= len(actual):
+			diffs = append(diffs, fmt.Sprintf("- %s = %s", childPath, formatJSON(expected[i])))
+		default:
+			diffs = append(diffs, jsonDiff(childPath, actual[i], expected[i])...)
+		}
+	}
+
+	return diffs
+}
+
+// formatJSON renders a decoded JSON value back to its compact JSON form for
+// use in diff output.
+func formatJSON(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(b)
+}
+
 // ResponseJsonField asserts that a specific JSON field has a specific value
 func ResponseJsonField(field string, expected string) TestOption {
 	return responseAssertion(func(t *testing.T, rr *httptest.ResponseRecorder) {
@@ -228,6 +811,303 @@ func ResponseJsonFieldPattern(field string, pattern string) TestOption {
 	})
 }
 
+// FieldMatcher validates a single value resolved from a JSON path, returning
+// a descriptive error when the value does not match.
+type FieldMatcher interface {
+	Match(value interface{}) error
+}
+
+// Equals matches a value that is deep-equal to v
+func Equals(v interface{}) FieldMatcher {
+	return equalsMatcher{v}
+}
+
+type equalsMatcher struct {
+	expected interface{}
+}
+
+func (m equalsMatcher) Match(value interface{}) error {
+	if !reflect.DeepEqual(value, m.expected) {
+		return fmt.Errorf("expected %s, received %s", formatJSON(m.expected), formatJSON(value))
+	}
+	return nil
+}
+
+// Regex matches a string value against a regular expression pattern
+func Regex(pattern string) FieldMatcher {
+	return regexMatcher{pattern}
+}
+
+type regexMatcher struct {
+	pattern string
+}
+
+func (m regexMatcher) Match(value interface{}) error {
+	s, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("expected a string matching pattern %s, received %s", m.pattern, formatJSON(value))
+	}
+
+	matched, err := regexp.MatchString(m.pattern, s)
+	if err != nil {
+		return fmt.Errorf("invalid pattern %s: %v", m.pattern, err)
+	}
+	if !matched {
+		return fmt.Errorf("expected a value matching pattern %s, received %q", m.pattern, s)
+	}
+	return nil
+}
+
+// TypeOf matches a value of the given JSON kind: "string", "number", "bool", "array", "object", or "null"
+func TypeOf(kind string) FieldMatcher {
+	return typeOfMatcher{kind}
+}
+
+type typeOfMatcher struct {
+	kind string
+}
+
+func (m typeOfMatcher) Match(value interface{}) error {
+	if actual := jsonKind(value); actual != m.kind {
+		return fmt.Errorf("expected type %s, received type %s (%s)", m.kind, actual, formatJSON(value))
+	}
+	return nil
+}
+
+func jsonKind(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "bool"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// Length matches an array, object, or string with the given length
+func Length(n int) FieldMatcher {
+	return lengthMatcher{n}
+}
+
+type lengthMatcher struct {
+	expected int
+}
+
+func (m lengthMatcher) Match(value interface{}) error {
+	var actual int
+	switch v := value.(type) {
+	case []interface{}:
+		actual = len(v)
+	case map[string]interface{}:
+		actual = len(v)
+	case string:
+		actual = len(v)
+	default:
+		return fmt.Errorf("expected a value with a length, received %s", formatJSON(value))
+	}
+
+	if actual != m.expected {
+		return fmt.Errorf("expected length %d, received length %d", m.expected, actual)
+	}
+	return nil
+}
+
+// OneOf matches a value that is deep-equal to any of values
+func OneOf(values ...interface{}) FieldMatcher {
+	return oneOfMatcher{values}
+}
+
+type oneOfMatcher struct {
+	values []interface{}
+}
+
+func (m oneOfMatcher) Match(value interface{}) error {
+	for _, v := range m.values {
+		if reflect.DeepEqual(v, value) {
+			return nil
+		}
+	}
+	return fmt.Errorf("expected one of %s, received %s", formatJSON(m.values), formatJSON(value))
+}
+
+// jsonPathSegment is one parsed step of a JSONPath-style string: either a
+// dotted object key or a "[N]" array index.
+type jsonPathSegment struct {
+	key     string
+	isIndex bool
+	index   int
+}
+
+var jsonPathTokenPattern = regexp.MustCompile(`\.[^.\[\]]+|\[\d+\]`)
+
+// parseJSONPath parses a path string like ".users[0].address.city" into a
+// sequence of segments
+func parseJSONPath(path string) ([]jsonPathSegment, error) {
+	tokens := jsonPathTokenPattern.FindAllString(path, -1)
+	if strings.Join(tokens, "") != path {
+		return nil, fmt.Errorf("invalid JSON path %q", path)
+	}
+
+	segments := make([]jsonPathSegment, 0, len(tokens))
+	for _, token := range tokens {
+		if strings.HasPrefix(token, "[") {
+			index, err := strconv.Atoi(token[1 : len(token)-1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid array index %q in path %q", token, path)
+			}
+			segments = append(segments, jsonPathSegment{isIndex: true, index: index})
+		} else {
+			segments = append(segments, jsonPathSegment{key: token[1:]})
+		}
+	}
+
+	return segments, nil
+}
+
+// resolveJSONPath walks root according to segments, returning the resolved
+// value. On a missing intermediate key or index, the error names the
+// deepest prefix of the path that could be resolved.
+func resolveJSONPath(root interface{}, segments []jsonPathSegment) (interface{}, error) {
+	current := root
+	resolved := ""
+
+	for _, segment := range segments {
+		if segment.isIndex {
+			arr, ok := current.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%q is not an array, deepest resolvable prefix is %q", resolved, resolved)
+			}
+			if segment.index < 0 || segment.index >= len(arr) {
+				return nil, fmt.Errorf("index %d out of range, deepest resolvable prefix is %q", segment.index, resolved)
+			}
+			current = arr[segment.index]
+			resolved = fmt.Sprintf("%s[%d]", resolved, segment.index)
+		} else {
+			obj, ok := current.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%q is not an object, deepest resolvable prefix is %q", resolved, resolved)
+			}
+			value, ok := obj[segment.key]
+			if !ok {
+				return nil, fmt.Errorf("missing key %q, deepest resolvable prefix is %q", segment.key, resolved)
+			}
+			current = value
+			resolved = resolved + "." + segment.key
+		}
+	}
+
+	return current, nil
+}
+
+// ResponseJsonPath asserts that the value at a JSONPath-style path (dotted
+// keys and "[N]" array indexing, e.g. ".users[0].address.city") satisfies matcher
+func ResponseJsonPath(path string, matcher FieldMatcher) TestOption {
+	return responseAssertion(func(t *testing.T, rr *httptest.ResponseRecorder) {
+		segments, err := parseJSONPath(path)
+		if err != nil {
+			t.Errorf("Invalid JSON path %q: %v", path, err)
+			return
+		}
+
+		var response interface{}
+		if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+			t.Errorf("Could not JSON parse response body: received\n\n%v", rr.Body)
+			return
+		}
+
+		value, err := resolveJSONPath(response, segments)
+		if err != nil {
+			t.Errorf("Could not resolve JSON path %q: %v", path, err)
+			return
+		}
+
+		if err := matcher.Match(value); err != nil {
+			t.Errorf("Unexpected value at JSON path %q: %v", path, err)
+		}
+	})
+}
+
+// ResponseBodyGolden asserts that the response body matches the golden file at testdata/path.
+// Run tests with -update to overwrite the golden file with the actual response instead.
+func ResponseBodyGolden(path string) TestOption {
+	return responseAssertion(func(t *testing.T, rr *httptest.ResponseRecorder) {
+		goldenPath := filepath.Join("testdata", path)
+		actual := rr.Body.Bytes()
+
+		if *updateGolden {
+			if err := os.WriteFile(goldenPath, actual, 0644); err != nil {
+				t.Fatalf("Could not write golden file %s: %v", goldenPath, err)
+			}
+			return
+		}
+
+		expected, err := os.ReadFile(goldenPath)
+		if err != nil {
+			t.Fatalf("Could not read golden file %s: %v (run tests with -update to create it)", goldenPath, err)
+		}
+
+		if !bytes.Equal(actual, expected) {
+			t.Errorf("Response body does not match golden file %s: received\n\n%s\n\nexpected\n\n%s", goldenPath, actual, expected)
+		}
+	})
+}
+
+// ResponseJSONGolden asserts that the response body is JSON-equivalent to the golden file at
+// testdata/path. Both sides are canonicalized (sorted keys, standard indentation) before
+// comparison so formatting churn doesn't cause spurious diffs. Run tests with -update to
+// overwrite the golden file with the actual response instead.
+func ResponseJSONGolden(path string) TestOption {
+	return responseAssertion(func(t *testing.T, rr *httptest.ResponseRecorder) {
+		goldenPath := filepath.Join("testdata", path)
+
+		actualCanonical, err := canonicalizeJSON(rr.Body.Bytes())
+		if err != nil {
+			t.Errorf("Could not JSON parse response body: received\n\n%v", rr.Body)
+			return
+		}
+
+		if *updateGolden {
+			if err := os.WriteFile(goldenPath, actualCanonical, 0644); err != nil {
+				t.Fatalf("Could not write golden file %s: %v", goldenPath, err)
+			}
+			return
+		}
+
+		expected, err := os.ReadFile(goldenPath)
+		if err != nil {
+			t.Fatalf("Could not read golden file %s: %v (run tests with -update to create it)", goldenPath, err)
+		}
+
+		expectedCanonical, err := canonicalizeJSON(expected)
+		if err != nil {
+			t.Fatalf("Could not JSON parse golden file %s: %v", goldenPath, err)
+		}
+
+		if !bytes.Equal(actualCanonical, expectedCanonical) {
+			t.Errorf("Response JSON does not match golden file %s: received\n\n%s\n\nexpected\n\n%s", goldenPath, actualCanonical, expectedCanonical)
+		}
+	})
+}
+
+// canonicalizeJSON decodes and re-encodes data with sorted keys and standard indentation, so
+// formatting differences don't affect comparison
+func canonicalizeJSON(data []byte) ([]byte, error) {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(value, "", "  ")
+}
+
 /*
 ---
 
@@ -246,6 +1126,6 @@ func Before(check Check) TestOption {
 
 func After(check Check) TestOption {
 	return func (test *TestConfig) {
-		test.precheck = append(test.postcheck, check)
+		test.postcheck = append(test.postcheck, check)
 	}
 }
\ No newline at end of file
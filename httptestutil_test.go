@@ -0,0 +1,485 @@
+package httptestutil
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestRunServerWiresExpectRequests guards against a regression where RunServer spun up its
+// httptest.Server around the raw handler and ignored test.requestAssertions entirely, so
+// ExpectRequests assertions were never invoked and ExpectRequests + RunServer silently verified
+// nothing.
+func TestRunServerWiresExpectRequests(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	var observedPath string
+	observe := func(t *testing.T, req *http.Request) {
+		observedPath = req.URL.Path
+	}
+
+	tests := TestSet{
+		Test("check wiring",
+			RequestMethod(http.MethodGet),
+			RequestRel("/actual"),
+			ExpectRequests(observe),
+		),
+	}
+
+	tests.RunServer(t, handler)
+
+	if observedPath != "/actual" {
+		t.Fatalf("expected RunServer to invoke the ExpectRequests assertion with the real request, received path %q", observedPath)
+	}
+}
+
+// TestRequestMultipartFieldsAndFiles checks that RequestMultipart produces a correctly-formed
+// multipart/form-data body, including a file field sourced from a plain io.Reader rather than an
+// in-memory byte slice.
+func TestRequestMultipartFieldsAndFiles(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatalf("could not parse multipart form: %v", err)
+		}
+
+		if got := r.FormValue("title"); got != "hello" {
+			t.Errorf("unexpected field value: received %q, expected %q", got, "hello")
+		}
+
+		file, header, err := r.FormFile("upload")
+		if err != nil {
+			t.Fatalf("could not read uploaded file: %v", err)
+		}
+		defer file.Close()
+
+		if header.Filename != "data.txt" {
+			t.Errorf("unexpected filename: received %q, expected %q", header.Filename, "data.txt")
+		}
+
+		content, err := io.ReadAll(file)
+		if err != nil {
+			t.Fatalf("could not read file content: %v", err)
+		}
+		if string(content) != "streamed content" {
+			t.Errorf("unexpected file content: received %q, expected %q", content, "streamed content")
+		}
+
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := TestSet{
+		Test("multipart upload",
+			RequestMethod(http.MethodPost),
+			RequestRel("/upload"),
+			RequestMultipart(
+				map[string]string{"title": "hello"},
+				map[string]FileSpec{"upload": {Filename: "data.txt", Content: strings.NewReader("streamed content")}},
+			),
+			ResponseStatus(http.StatusOK),
+		),
+	}
+
+	tests.Run(t, handler)
+}
+
+// TestRequestMultipartDoesNotLeakGoroutineWhenBodyUnread guards against a regression where the
+// goroutine started by RequestMultipart's bodyFactory blocked forever on its io.Pipe write
+// whenever the handler under test never reads the request body to EOF (e.g. a handler that
+// rejects the request before parsing it) — a normal scenario for Run/RunParallel, which unlike
+// RunServer never drain the body on the caller's behalf.
+func TestRequestMultipartDoesNotLeakGoroutineWhenBodyUnread(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	})
+
+	before := runtime.NumGoroutine()
+
+	tests := TestSet{
+		Test("handler never reads body",
+			RequestMethod(http.MethodPost),
+			RequestRel("/upload"),
+			RequestMultipart(
+				map[string]string{"title": "hello"},
+				map[string]FileSpec{"upload": {Filename: "data.txt", Content: strings.NewReader("streamed content")}},
+			),
+			ResponseStatus(http.StatusBadRequest),
+		),
+	}
+
+	tests.Run(t, handler)
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		if runtime.NumGoroutine() <= before {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("multipart writer goroutine appears to have leaked: had %d goroutines before Run, still have %d", before, runtime.NumGoroutine())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestParseJSONPath(t *testing.T) {
+	segments, err := parseJSONPath(".users[0].address.city")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []jsonPathSegment{
+		{key: "users"},
+		{isIndex: true, index: 0},
+		{key: "address"},
+		{key: "city"},
+	}
+	if !reflect.DeepEqual(segments, expected) {
+		t.Fatalf("received %+v, expected %+v", segments, expected)
+	}
+}
+
+func TestParseJSONPathInvalid(t *testing.T) {
+	if _, err := parseJSONPath(".users[abc]"); err == nil {
+		t.Fatal("expected an error for a non-numeric array index")
+	}
+}
+
+func TestResolveJSONPath(t *testing.T) {
+	root := map[string]interface{}{
+		"users": []interface{}{
+			map[string]interface{}{"address": map[string]interface{}{"city": "Springfield"}},
+		},
+	}
+
+	segments, err := parseJSONPath(".users[0].address.city")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	value, err := resolveJSONPath(root, segments)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if value != "Springfield" {
+		t.Fatalf("received %v, expected %v", value, "Springfield")
+	}
+}
+
+func TestResolveJSONPathMissingKeyNamesDeepestPrefix(t *testing.T) {
+	root := map[string]interface{}{
+		"users": []interface{}{
+			map[string]interface{}{"address": map[string]interface{}{"city": "Springfield"}},
+		},
+	}
+
+	segments, err := parseJSONPath(".users[0].address.zip")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err = resolveJSONPath(root, segments)
+	if err == nil {
+		t.Fatal("expected an error for a missing key")
+	}
+	if !strings.Contains(err.Error(), `".users[0].address"`) {
+		t.Fatalf("expected error to name the deepest resolvable prefix, received: %v", err)
+	}
+}
+
+func TestFieldMatchers(t *testing.T) {
+	cases := []struct {
+		name    string
+		matcher FieldMatcher
+		value   interface{}
+		wantErr bool
+	}{
+		{"Equals match", Equals("a"), "a", false},
+		{"Equals mismatch", Equals("a"), "b", true},
+		{"Regex match", Regex("^[0-9]+$"), "123", false},
+		{"Regex mismatch", Regex("^[0-9]+$"), "abc", true},
+		{"TypeOf match", TypeOf("number"), 1.0, false},
+		{"TypeOf mismatch", TypeOf("number"), "1", true},
+		{"Length match", Length(3), "abc", false},
+		{"Length mismatch", Length(3), "ab", true},
+		{"OneOf match", OneOf("a", "b"), "b", false},
+		{"OneOf mismatch", OneOf("a", "b"), "c", true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.matcher.Match(tc.value)
+			if tc.wantErr && err == nil {
+				t.Fatal("expected an error, received nil")
+			}
+			if !tc.wantErr && err != nil {
+				t.Fatalf("expected no error, received: %v", err)
+			}
+		})
+	}
+}
+
+func TestJsonDiffEqual(t *testing.T) {
+	diff := jsonDiff("", map[string]interface{}{"a": 1.0, "b": "x"}, map[string]interface{}{"a": 1.0, "b": "x"})
+	if len(diff) != 0 {
+		t.Fatalf("expected no diff for equal values, received %v", diff)
+	}
+}
+
+func TestJsonDiffScalarChange(t *testing.T) {
+	diff := jsonDiff("", map[string]interface{}{"status": "fail"}, map[string]interface{}{"status": "ok"})
+	expected := []string{`~ .status: "ok" -> "fail"`}
+	if !reflect.DeepEqual(diff, expected) {
+		t.Fatalf("received %v, expected %v", diff, expected)
+	}
+}
+
+func TestJsonDiffAddedAndRemovedKeys(t *testing.T) {
+	actual := map[string]interface{}{"id": 7.0}
+	expected := map[string]interface{}{"name": "a"}
+
+	diff := jsonDiff("", actual, expected)
+	sort.Strings(diff)
+
+	expectedDiff := []string{`+ .id = 7`, `- .name = "a"`}
+	if !reflect.DeepEqual(diff, expectedDiff) {
+		t.Fatalf("received %v, expected %v", diff, expectedDiff)
+	}
+}
+
+func TestJsonDiffNestedArray(t *testing.T) {
+	actual := map[string]interface{}{
+		"users": []interface{}{
+			map[string]interface{}{"id": 7.0},
+		},
+	}
+	expected := map[string]interface{}{
+		"users": []interface{}{
+			map[string]interface{}{"id": 7.0, "name": "a"},
+		},
+	}
+
+	diff := jsonDiff("", actual, expected)
+	expectedDiff := []string{`- .users[0].name = "a"`}
+	if !reflect.DeepEqual(diff, expectedDiff) {
+		t.Fatalf("received %v, expected %v", diff, expectedDiff)
+	}
+}
+
+func TestResponseJSONEq(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"b": 2, "a": 1}`)
+	})
+
+	tests := TestSet{
+		Test("reordered keys still match",
+			RequestRel("/"),
+			ResponseJSONEq(map[string]interface{}{"a": 1, "b": 2}),
+		),
+	}
+
+	tests.Run(t, handler)
+}
+
+func TestCanonicalizeJSON(t *testing.T) {
+	out, err := canonicalizeJSON([]byte(`{"b":2,"a":1}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "{\n  \"a\": 1,\n  \"b\": 2\n}"
+	if string(out) != expected {
+		t.Fatalf("received %q, expected %q", out, expected)
+	}
+}
+
+// withTestdataDir chdirs into a fresh directory containing an empty testdata/ subdirectory for
+// the duration of the test, restoring the original working directory afterward.
+func withTestdataDir(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "testdata"), 0755); err != nil {
+		t.Fatalf("could not create testdata dir: %v", err)
+	}
+
+	original, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("could not get working directory: %v", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("could not chdir: %v", err)
+	}
+	t.Cleanup(func() { os.Chdir(original) })
+
+	return dir
+}
+
+func TestResponseBodyGoldenUpdateThenMatch(t *testing.T) {
+	withTestdataDir(t)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "hello golden")
+	})
+
+	*updateGolden = true
+	TestSet{Test("write golden", RequestRel("/"), ResponseBodyGolden("body.golden"))}.Run(t, handler)
+
+	*updateGolden = false
+	TestSet{Test("match golden", RequestRel("/"), ResponseBodyGolden("body.golden"))}.Run(t, handler)
+}
+
+func TestResponseJSONGoldenIgnoresFormatting(t *testing.T) {
+	dir := withTestdataDir(t)
+
+	goldenPath := filepath.Join(dir, "testdata", "response.json")
+	if err := os.WriteFile(goldenPath, []byte(`{"a": 1, "b": 2}`), 0644); err != nil {
+		t.Fatalf("could not write golden fixture: %v", err)
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// different key order and whitespace than the golden file, but equivalent JSON
+		io.WriteString(w, `{"b":2,"a":1}`)
+	})
+
+	tests := TestSet{
+		Test("formatting differs but content matches", RequestRel("/"), ResponseJSONGolden("response.json")),
+	}
+	tests.Run(t, handler)
+}
+
+// TestSuiteAddProducesEquivalentTestToDirectConstruction checks that building a test via
+// NewSuite()...Add() sends the same request and runs the same response assertions as assembling
+// the equivalent TestConfig directly through Test(...) with matching options.
+func TestSuiteAddProducesEquivalentTestToDirectConstruction(t *testing.T) {
+	var gotMethod, gotPath, gotAuth string
+	var gotBody []byte
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod = r.Method
+		gotPath = r.URL.Path
+		gotAuth = r.Header.Get("Authorization")
+		gotBody, _ = io.ReadAll(r.Body)
+		w.Header().Set("X-Token", "abc")
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	payload := map[string]interface{}{"name": "widget"}
+
+	suiteTests := NewSuite().
+		Post("/widgets").
+		WithHeader("Authorization", "Bearer token").
+		WithJSON(payload).
+		ExpectStatus(http.StatusCreated).
+		ExpectHeader("X-Token", "abc").
+		Add().
+		Build()
+
+	if len(suiteTests) != 1 {
+		t.Fatalf("expected Suite to build 1 test, received %d", len(suiteTests))
+	}
+	if suiteTests[0].name != "POST /widgets" {
+		t.Fatalf("expected default test name %q, received %q", "POST /widgets", suiteTests[0].name)
+	}
+
+	suiteTests.Run(t, handler)
+
+	if gotMethod != http.MethodPost || gotPath != "/widgets" || gotAuth != "Bearer token" {
+		t.Fatalf("Suite-built test sent method=%q path=%q auth=%q, expected method=%q path=%q auth=%q", gotMethod, gotPath, gotAuth, http.MethodPost, "/widgets", "Bearer token")
+	}
+	suiteBody := gotBody
+
+	// Reset and run the equivalent TestSet assembled directly via Test(...), to confirm
+	// Suite/Add() is just sugar over the same building blocks rather than a divergent code path.
+	gotMethod, gotPath, gotAuth, gotBody = "", "", "", nil
+
+	directTests := TestSet{
+		Test("POST /widgets",
+			RequestMethod(http.MethodPost),
+			RequestRel("/widgets"),
+			RequestHeader("Authorization", "Bearer token"),
+			RequestJSON(payload),
+			ResponseStatus(http.StatusCreated),
+			ResponseHeader("X-Token", "abc"),
+		),
+	}
+	directTests.Run(t, handler)
+
+	if gotMethod != http.MethodPost || gotPath != "/widgets" || gotAuth != "Bearer token" {
+		t.Fatalf("direct Test(...) construction sent method=%q path=%q auth=%q, expected method=%q path=%q auth=%q", gotMethod, gotPath, gotAuth, http.MethodPost, "/widgets", "Bearer token")
+	}
+	if string(gotBody) != string(suiteBody) {
+		t.Fatalf("Suite-built and directly-constructed tests sent different bodies: %q vs %q", suiteBody, gotBody)
+	}
+}
+
+// TestRunParallelRunsSubtestsWithIsolatedConfig guards TestConfig.clone(), which RunParallel
+// relies on to give each parallel subtest its own copy of modifier/assertion slices. Run with
+// -race, this would catch a shared backing array being mutated concurrently.
+func TestRunParallelRunsSubtestsWithIsolatedConfig(t *testing.T) {
+	var mu sync.Mutex
+	seen := map[string]string{}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		seen[r.URL.Path] = r.Header.Get("X-Widget")
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := TestSet{
+		Test("widget one", RequestRel("/widgets/1"), RequestHeader("X-Widget", "one"), ResponseStatus(http.StatusOK)),
+		Test("widget two", RequestRel("/widgets/2"), RequestHeader("X-Widget", "two"), ResponseStatus(http.StatusOK)),
+		Test("widget three", RequestRel("/widgets/3"), RequestHeader("X-Widget", "three"), ResponseStatus(http.StatusOK)),
+	}
+
+	// t.Run blocks until its parallel subtests (paused by t.Parallel()) have all finished, so the
+	// assertions below only run once every RunParallel subtest has completed.
+	t.Run("group", func(t *testing.T) {
+		tests.RunParallel(t, handler)
+	})
+
+	mu.Lock()
+	defer mu.Unlock()
+	expected := map[string]string{"/widgets/1": "one", "/widgets/2": "two", "/widgets/3": "three"}
+	if !reflect.DeepEqual(seen, expected) {
+		t.Fatalf("received %v, expected %v", seen, expected)
+	}
+}
+
+// TestAfterRunsPostcheckAfterResponse guards against a regression where After appended its check
+// to precheck instead of postcheck, which would run it before the handler instead of after the
+// response.
+func TestAfterRunsPostcheckAfterResponse(t *testing.T) {
+	var order []string
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		order = append(order, "handler")
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := TestSet{
+		Test("after runs post-response",
+			RequestRel("/"),
+			Before(func(t *testing.T) { order = append(order, "before") }),
+			After(func(t *testing.T) { order = append(order, "after") }),
+			ResponseStatus(http.StatusOK),
+		),
+	}
+
+	tests.Run(t, handler)
+
+	expected := []string{"before", "handler", "after"}
+	if !reflect.DeepEqual(order, expected) {
+		t.Fatalf("received order %v, expected %v", order, expected)
+	}
+}